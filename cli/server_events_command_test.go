@@ -0,0 +1,62 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import "testing"
+
+func TestParseEventFilters(t *testing.T) {
+	cases := []struct {
+		in   string
+		want map[string]string
+	}{
+		{"", map[string]string{}},
+		{"kind=connect", map[string]string{"kind": "connect"}},
+		{"kind=connect,cluster=east", map[string]string{"kind": "connect", "cluster": "east"}},
+		{"bad", map[string]string{}},
+	}
+
+	for _, c := range cases {
+		got := parseEventFilters(c.in)
+		if len(got) != len(c.want) {
+			t.Fatalf("parseEventFilters(%q) = %v, want %v", c.in, got, c.want)
+		}
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Fatalf("parseEventFilters(%q) = %v, want %v", c.in, got, c.want)
+			}
+		}
+	}
+}
+
+func TestSrvEventMatches(t *testing.T) {
+	ev := &srvEvent{Kind: "connect", Cluster: "east", Server: "s1", Account: "APP"}
+
+	cases := []struct {
+		name    string
+		filters map[string]string
+		want    bool
+	}{
+		{"no filters", map[string]string{}, true},
+		{"matching kind", map[string]string{"kind": "connect"}, true},
+		{"mismatched kind", map[string]string{"kind": "disconnect"}, false},
+		{"matching cluster and server", map[string]string{"cluster": "east", "server": "s1"}, true},
+		{"mismatched account", map[string]string{"account": "OTHER"}, false},
+	}
+
+	for _, c := range cases {
+		if got := ev.matches(c.filters); got != c.want {
+			t.Fatalf("%s: matches() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
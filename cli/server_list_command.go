@@ -16,6 +16,8 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -25,14 +27,28 @@ import (
 	"github.com/choria-io/fisk"
 	"github.com/dustin/go-humanize"
 	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 )
 
 type SrvLsCmd struct {
-	expect  uint32
-	json    bool
-	sort    string
-	reverse bool
-	compact bool
+	expect    uint32
+	json      bool
+	output    string
+	sort      string
+	reverse   bool
+	compact   bool
+	watch     bool
+	interval  time.Duration
+	cpuLimit  float64
+	memLimit  uint64
+	slowLimit int64
+
+	anomalies     bool
+	anomalyZ      float64
+	failOnAnomaly bool
+
+	hist       *srvLsHistory
+	streamSink func(*srvLsResult)
 }
 
 type srvListCluster struct {
@@ -43,15 +59,176 @@ type srvListCluster struct {
 	conns int
 }
 
+// srvLsHistory keeps the previous poll's results around so that --watch
+// mode can render per-server deltas and small sparkline trends instead of
+// just a static snapshot.
+type srvLsHistory struct {
+	mu       sync.Mutex
+	prev     map[string]*server.ServerStatsMsg
+	prevAt   map[string]time.Time
+	prevRTT  map[string]time.Duration
+	inSpark  map[string][]float64
+	outSpark map[string][]float64
+	deltas   map[string]srvLsDelta
+}
+
+func newSrvLsHistory() *srvLsHistory {
+	return &srvLsHistory{
+		prev:     make(map[string]*server.ServerStatsMsg),
+		prevAt:   make(map[string]time.Time),
+		prevRTT:  make(map[string]time.Duration),
+		inSpark:  make(map[string][]float64),
+		outSpark: make(map[string][]float64),
+		deltas:   make(map[string]srvLsDelta),
+	}
+}
+
+const srvLsSparkWidth = 12
+
+// srvLsDelta captures the per-server deltas computed between two polls.
+type srvLsDelta struct {
+	inMsgsPerSec  float64
+	outMsgsPerSec float64
+	connDelta     int
+	gwDelta       int
+	rttJitter     time.Duration
+}
+
+// update records ssm as the latest sample for its server and returns the
+// deltas against the previous sample, if any. The same delta is also cached
+// and can be read back later with delta().
+func (h *srvLsHistory) update(ssm *server.ServerStatsMsg, rtt time.Duration, now time.Time) srvLsDelta {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name := ssm.Server.Name
+	var delta srvLsDelta
+
+	if prev, ok := h.prev[name]; ok {
+		elapsed := now.Sub(h.prevAt[name]).Seconds()
+		if elapsed > 0 {
+			delta.inMsgsPerSec = float64(ssm.Stats.InMsgs-prev.Stats.InMsgs) / elapsed
+			delta.outMsgsPerSec = float64(ssm.Stats.OutMsgs-prev.Stats.OutMsgs) / elapsed
+		}
+		delta.connDelta = ssm.Stats.Connections - prev.Stats.Connections
+		delta.gwDelta = len(ssm.Stats.Gateways) - len(prev.Stats.Gateways)
+
+		delta.rttJitter = rtt - h.prevRTT[name]
+		if delta.rttJitter < 0 {
+			delta.rttJitter = -delta.rttJitter
+		}
+	}
+
+	h.inSpark[name] = appendSpark(h.inSpark[name], delta.inMsgsPerSec)
+	h.outSpark[name] = appendSpark(h.outSpark[name], delta.outMsgsPerSec)
+	h.deltas[name] = delta
+
+	h.prev[name] = ssm
+	h.prevAt[name] = now
+	h.prevRTT[name] = rtt
+
+	return delta
+}
+
+// delta returns the most recently computed delta for name, as recorded by
+// update().
+func (h *srvLsHistory) delta(name string) srvLsDelta {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.deltas[name]
+}
+
+func appendSpark(series []float64, v float64) []float64 {
+	series = append(series, v)
+	if len(series) > srvLsSparkWidth {
+		series = series[len(series)-srvLsSparkWidth:]
+	}
+	return series
+}
+
+func (h *srvLsHistory) inSparkline(name string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return sparkline(h.inSpark[name])
+}
+
+func (h *srvLsHistory) outSparkline(name string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return sparkline(h.outSpark[name])
+}
+
+var sparkBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// sparkline renders a series of non-negative values as a single line of
+// unicode block characters scaled between the series' own min and max.
+func sparkline(series []float64) string {
+	if len(series) == 0 {
+		return ""
+	}
+
+	min, max := series[0], series[0]
+	for _, v := range series {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	span := max - min
+	out := make([]rune, len(series))
+	for i, v := range series {
+		if span <= 0 {
+			out[i] = sparkBlocks[0]
+			continue
+		}
+		idx := int((v - min) / span * float64(len(sparkBlocks)-1))
+		out[i] = sparkBlocks[idx]
+	}
+
+	return string(out)
+}
+
 func configureServerListCommand(srv *fisk.CmdClause) {
 	c := &SrvLsCmd{}
 
 	ls := srv.Command("ls", "List known servers").Alias("list").Action(c.list)
 	ls.Arg("expect", "How many servers to expect").Uint32Var(&c.expect)
 	ls.Flag("json", "Produce JSON output").Short('j').UnNegatableBoolVar(&c.json)
+	ls.Flag("output", "Output format: table, json, csv, ndjson or template=<go-template>").Default("table").StringVar(&c.output)
 	ls.Flag("sort", "Sort servers by a specific key (name,conns,subs,routes,gws,mem,cpu,slow,uptime,rtt").Default("name").EnumVar(&c.sort, strings.Split("name,conns,conn,subs,sub,routes,route,gw,mem,cpu,slow,uptime,rtt", ",")...)
 	ls.Flag("reverse", "Reverse sort servers").Short('R').UnNegatableBoolVar(&c.reverse)
 	ls.Flag("compact", "Compact server names").Default("true").BoolVar(&c.compact)
+	ls.Flag("watch", "Keep running and redraw the overview on each --interval, like top").UnNegatableBoolVar(&c.watch)
+	ls.Flag("interval", "Refresh interval used with --watch").Default("2s").DurationVar(&c.interval)
+	ls.Flag("cpu-limit", "CPU %% above which a server is highlighted in --watch mode").Default("90").Float64Var(&c.cpuLimit)
+	ls.Flag("mem-limit", "Memory usage in bytes above which a server is highlighted in --watch mode").Default("0").Uint64Var(&c.memLimit)
+	ls.Flag("slow-limit", "Slow consumer count above which a server is highlighted in --watch mode").Default("0").Int64Var(&c.slowLimit)
+	ls.Flag("anomalies", "Run a statistical pass over the results and report servers that deviate from the cluster").UnNegatableBoolVar(&c.anomalies)
+	ls.Flag("anomaly-zscore", "Z-score above which a server is flagged as an anomaly").Default("3.0").Float64Var(&c.anomalyZ)
+	ls.Flag("fail-on-anomaly", "Exit non-zero when an anomaly is detected, for use in CI/monitoring").UnNegatableBoolVar(&c.failOnAnomaly)
+}
+
+// srvLsResult pairs a server ping reply with the RTT it took to arrive. RTT
+// is exported so it survives json.Marshal for the json/ndjson --output
+// encoders, unlike the embedded ServerStatsMsg fields which already export
+// what they need.
+type srvLsResult struct {
+	*server.ServerStatsMsg
+	RTT time.Duration `json:"rtt"`
+}
+
+// srvLsTotals holds the cluster-wide aggregates accumulated while fan-in of
+// ping replies is in progress.
+type srvLsTotals struct {
+	servers     int
+	connections int
+	memory      int64
+	slow        int64
+	subs        uint32
+	js          int
 }
 
 func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
@@ -61,29 +238,113 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 	}
 	defer nc.Close()
 
-	type result struct {
-		*server.ServerStatsMsg
-		rtt time.Duration
+	if c.watch {
+		return c.watchLoop(nc)
+	}
+
+	// --json predates --output and keeps working as a shorthand for it
+	output := c.output
+	if c.json {
+		output = "json"
+	}
+
+	if output != "" && output != "table" {
+		return c.listEncoded(nc, output)
 	}
 
+	results, clusters, totals, err := c.poll(nc)
+	if err != nil {
+		return err
+	}
+
+	c.render(results, clusters, totals)
+
+	return c.checkAnomalies(results, os.Stdout)
+}
+
+// listEncoded polls the cluster and renders the results through one of the
+// pluggable SrvLsEncoder implementations selected by --output, rather than
+// the interactive table, so `nats srv ls` output can be piped into other
+// tools.
+func (c *SrvLsCmd) listEncoded(nc *nats.Conn, output string) error {
+	enc, err := newSrvLsEncoder(output)
+	if err != nil {
+		return err
+	}
+
+	if enc.streams() {
+		c.streamSink = func(r *srvLsResult) {
+			if err := enc.Server(r); err != nil {
+				log.Printf("Could not encode result: %s", err)
+			}
+		}
+	}
+
+	results, clusters, totals, err := c.poll(nc)
+	if err != nil {
+		return err
+	}
+
+	if !enc.streams() {
+		enc.Prepare(clusters, totals)
+
+		c.sortResults(results)
+		for _, r := range results {
+			if err := enc.Server(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	// --anomalies/--fail-on-anomaly is the CI/monitoring use case, which
+	// almost always pairs with structured --output, so it has to run here
+	// too, not just on the table path. The anomalies table itself goes to
+	// stderr so it doesn't corrupt the machine-readable stdout stream.
+	return c.checkAnomalies(results, os.Stderr)
+}
+
+// watchLoop keeps polling the cluster every --interval, redrawing the
+// overview tables in place like top(1) until the user interrupts it.
+func (c *SrvLsCmd) watchLoop(nc *nats.Conn) error {
+	c.hist = newSrvLsHistory()
+
+	for {
+		results, clusters, totals, err := c.poll(nc)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print("\x1b[H\x1b[2J")
+		fmt.Printf("nats srv ls --watch  |  refresh every %s  |  %s\n\n", c.interval, time.Now().Format(time.RFC1123))
+
+		if c.json {
+			printJSON(results)
+		} else {
+			c.render(results, clusters, totals)
+		}
+
+		time.Sleep(c.interval)
+	}
+}
+
+// poll fans out a single $SYS.REQ.SERVER.PING request and collects the
+// replies along with the running cluster aggregates.
+func (c *SrvLsCmd) poll(nc *nats.Conn) ([]*srvLsResult, map[string]*srvListCluster, *srvLsTotals, error) {
 	var (
-		results     []*result
-		names       []string
-		hosts       []string
-		clusters    = make(map[string]*srvListCluster)
-		servers     int
-		connections int
-		memory      int64
-		slow        int64
-		subs        uint32
-		js          int
-		start       = time.Now()
-		mu          sync.Mutex
+		results  []*srvLsResult
+		clusters = make(map[string]*srvListCluster)
+		totals   = &srvLsTotals{}
+		start    = time.Now()
+		mu       sync.Mutex
 	)
 
 	doReqAsync(nil, "$SYS.REQ.SERVER.PING", int(c.expect), nc, func(data []byte) {
 		ssm := &server.ServerStatsMsg{}
-		err = json.Unmarshal(data, ssm)
+		err := json.Unmarshal(data, ssm)
 		if err != nil {
 			log.Printf("Could not decode response: %s", err)
 			os.Exit(1)
@@ -92,13 +353,13 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 		mu.Lock()
 		defer mu.Unlock()
 
-		servers++
-		connections += ssm.Stats.Connections
-		memory += ssm.Stats.Mem
-		slow += ssm.Stats.SlowConsumers
-		subs += ssm.Stats.NumSubs
+		totals.servers++
+		totals.connections += ssm.Stats.Connections
+		totals.memory += ssm.Stats.Mem
+		totals.slow += ssm.Stats.SlowConsumers
+		totals.subs += ssm.Stats.NumSubs
 		if ssm.Server.JetStream {
-			js++
+			totals.js++
 		}
 
 		cluster := ssm.Server.Cluster
@@ -116,21 +377,29 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 			}
 		}
 
-		results = append(results, &result{
+		r := &srvLsResult{
 			ServerStatsMsg: ssm,
-			rtt:            time.Since(start),
-		})
+			RTT:            time.Since(start),
+		}
+
+		results = append(results, r)
+
+		if c.streamSink != nil {
+			c.streamSink(r)
+		}
 	})
 
 	if len(results) == 0 {
-		return fmt.Errorf("no results received, ensure the account used has system privileges and appropriate permissions")
+		return nil, nil, nil, fmt.Errorf("no results received, ensure the account used has system privileges and appropriate permissions")
 	}
 
-	if c.json {
-		printJSON(results)
-		return nil
-	}
+	return results, clusters, totals, nil
+}
 
+// sortResults orders results in place according to --sort and --reverse, so
+// every output format (table, csv, template, ...) presents servers in the
+// same order.
+func (c *SrvLsCmd) sortResults(results []*srvLsResult) {
 	// we reverse sort by default now, setting reverse=true means
 	// do not reverse, so this function seems really weird but its right
 	rev := func(v bool) bool {
@@ -164,12 +433,28 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 		case "uptime":
 			return rev(stati.Start.UnixNano() > statj.Start.UnixNano())
 		default:
-			return rev(results[i].rtt > results[j].rtt)
+			return rev(results[i].RTT > results[j].RTT)
 		}
 	})
+}
+
+// render prints the Server Overview and Cluster Overview tables for a single
+// poll. When running under --watch it also prints per-server throughput
+// sparklines and a cluster health footer.
+func (c *SrvLsCmd) render(results []*srvLsResult, clusters map[string]*srvListCluster, totals *srvLsTotals) {
+	var (
+		names []string
+		hosts []string
+	)
+
+	c.sortResults(results)
 
 	table := newTableWriter("Server Overview")
-	table.AddHeaders("Name", "Cluster", "Host", "Version", "JS", "Conns", "Subs", "Routes", "GWs", "Mem", "CPU %", "Cores", "Slow", "Uptime", "RTT")
+	headers := []any{"Name", "Cluster", "Host", "Version", "JS", "Conns", "Subs", "Routes", "GWs", "Mem", "CPU %", "Cores", "Slow", "Uptime", "RTT"}
+	if c.watch {
+		headers = append(headers, "Conns Δ", "GWs Δ", "RTT Jitter", "In msg/s", "Out msg/s")
+	}
+	table.AddHeaders(headers...)
 
 	// here so its after the sort
 	for _, ssm := range results {
@@ -194,8 +479,13 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 			}
 		}
 
-		table.AddRow(
-			cNames[i],
+		name := cNames[i]
+		if c.watch && c.breachesLimits(ssm) {
+			name = highlight(name)
+		}
+
+		row := []any{
+			name,
 			cluster,
 			cHosts[i],
 			ssm.Server.Version,
@@ -209,25 +499,44 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 			ssm.Stats.Cores,
 			ssm.Stats.SlowConsumers,
 			humanizeDuration(ssm.Server.Time.Sub(ssm.Stats.Start)),
-			ssm.rtt.Round(time.Millisecond))
+			ssm.RTT.Round(time.Millisecond),
+		}
+
+		if c.watch {
+			delta := c.hist.update(ssm.ServerStatsMsg, ssm.RTT, time.Now())
+			row = append(row,
+				delta.connDelta,
+				delta.gwDelta,
+				delta.rttJitter.Round(time.Millisecond),
+				fmt.Sprintf("%.1f %s", delta.inMsgsPerSec, c.hist.inSparkline(ssm.Server.Name)),
+				fmt.Sprintf("%.1f %s", delta.outMsgsPerSec, c.hist.outSparkline(ssm.Server.Name)))
+		}
+
+		table.AddRow(row...)
 	}
 
-	table.AddSeparator()
-	table.AddRow(
+	footer := []any{
 		"",
 		len(clusters),
-		servers,
+		totals.servers,
 		"",
-		js,
-		humanize.Comma(int64(connections)),
-		humanize.Comma(int64(subs)),
+		totals.js,
+		humanize.Comma(int64(totals.connections)),
+		humanize.Comma(int64(totals.subs)),
 		"", "",
-		humanize.IBytes(uint64(memory)),
+		humanize.IBytes(uint64(totals.memory)),
 		"",
 		"",
-		humanize.Comma(slow),
+		humanize.Comma(totals.slow),
 		"",
-		"")
+		"",
+	}
+	if c.watch {
+		footer = append(footer, "", "", "", "", "")
+	}
+
+	table.AddSeparator()
+	table.AddRow(footer...)
 
 	fmt.Print(table.Render())
 
@@ -235,6 +544,308 @@ func (c *SrvLsCmd) list(_ *fisk.ParseContext) error {
 		c.showClusters(clusters)
 	}
 
+	if c.watch {
+		c.healthFooter(results, clusters, totals)
+	}
+}
+
+// breachesLimits reports whether ssm crosses any of the configured
+// --cpu-limit, --mem-limit or --slow-limit thresholds.
+func (c *SrvLsCmd) breachesLimits(ssm *srvLsResult) bool {
+	if c.cpuLimit > 0 && ssm.Stats.CPU >= c.cpuLimit {
+		return true
+	}
+	if c.memLimit > 0 && uint64(ssm.Stats.Mem) >= c.memLimit {
+		return true
+	}
+	if c.slowLimit > 0 && ssm.Stats.SlowConsumers >= c.slowLimit {
+		return true
+	}
+	return false
+}
+
+// highlight marks a value as breaching a threshold using a terminal red so
+// it stands out in a --watch session running like top(1).
+func highlight(s string) string {
+	return fmt.Sprintf("\x1b[31m%s\x1b[0m", s)
+}
+
+// healthFooter prints an aggregate cluster health line summarising totals,
+// per-cluster connection ranges and how many servers are currently over a
+// configured threshold.
+func (c *SrvLsCmd) healthFooter(results []*srvLsResult, clusters map[string]*srvListCluster, totals *srvLsTotals) {
+	breaching := 0
+	for _, r := range results {
+		if c.breachesLimits(r) {
+			breaching++
+		}
+	}
+
+	minConns, maxConns := -1, -1
+	for _, cl := range clusters {
+		if minConns == -1 || cl.conns < minConns {
+			minConns = cl.conns
+		}
+		if cl.conns > maxConns {
+			maxConns = cl.conns
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Cluster Health: %d servers, %d clusters, %s connections, %s subscriptions",
+		totals.servers, len(clusters), humanize.Comma(int64(totals.connections)), humanize.Comma(int64(totals.subs)))
+	if len(clusters) > 0 {
+		fmt.Printf(", connections per cluster %d..%d", minConns, maxConns)
+	}
+	if breaching > 0 {
+		fmt.Printf(", %s", highlight(fmt.Sprintf("%d server(s) over threshold", breaching)))
+	}
+	fmt.Println()
+}
+
+// srvLsAnomaly describes a single flagged value or structural discrepancy
+// found while scanning the polled results with --anomalies.
+type srvLsAnomaly struct {
+	server    string
+	cluster   string
+	metric    string
+	value     string
+	deviation string
+}
+
+// srvLsColumn extracts one numeric metric from a result, used to build the
+// per-column statistics the anomaly pass flags outliers against.
+type srvLsColumn struct {
+	name    string
+	extract func(*srvLsResult) float64
+}
+
+var srvLsAnomalyColumns = []srvLsColumn{
+	{"cpu", func(r *srvLsResult) float64 { return r.Stats.CPU }},
+	{"mem", func(r *srvLsResult) float64 { return float64(r.Stats.Mem) }},
+	{"subs", func(r *srvLsResult) float64 { return float64(r.Stats.NumSubs) }},
+	{"conns", func(r *srvLsResult) float64 { return float64(r.Stats.Connections) }},
+	{"slow", func(r *srvLsResult) float64 { return float64(r.Stats.SlowConsumers) }},
+	{"rtt", func(r *srvLsResult) float64 { return float64(r.RTT) }},
+	{"routes", func(r *srvLsResult) float64 { return float64(len(r.Stats.Routes)) }},
+}
+
+// meanStddev returns the mean and population standard deviation of values.
+func meanStddev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+
+	return mean, stddev
+}
+
+// medianMAD returns the median and median absolute deviation of values.
+func medianMAD(values []float64) (median, mad float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	median = sorted[len(sorted)/2]
+
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	sort.Float64s(deviations)
+	mad = deviations[len(deviations)/2]
+
+	return median, mad
+}
+
+// detectAnomalies runs the statistical and structural passes over results
+// and returns every flagged anomaly.
+func (c *SrvLsCmd) detectAnomalies(results []*srvLsResult) []srvLsAnomaly {
+	var anomalies []srvLsAnomaly
+
+	for _, col := range srvLsAnomalyColumns {
+		values := make([]float64, len(results))
+		for i, r := range results {
+			values[i] = col.extract(r)
+		}
+
+		mean, stddev := meanStddev(values)
+		median, mad := medianMAD(values)
+
+		for i, r := range results {
+			v := values[i]
+
+			if stddev > 0 {
+				z := (v - mean) / stddev
+				if math.Abs(z) >= c.anomalyZ {
+					anomalies = append(anomalies, srvLsAnomaly{
+						server: r.Server.Name, cluster: r.Server.Cluster, metric: col.name,
+						value:     fmt.Sprintf("%.2f", v),
+						deviation: fmt.Sprintf("z=%.2f", z),
+					})
+					continue
+				}
+			}
+
+			if mad > 0 {
+				// 0.6745 is the constant that makes the MAD-score comparable
+				// to a z-score for normally distributed data.
+				madScore := 0.6745 * (v - median) / mad
+				if math.Abs(madScore) >= c.anomalyZ {
+					anomalies = append(anomalies, srvLsAnomaly{
+						server: r.Server.Name, cluster: r.Server.Cluster, metric: col.name,
+						value:     fmt.Sprintf("%.2f", v),
+						deviation: fmt.Sprintf("mad=%.2f", madScore),
+					})
+				}
+			}
+		}
+	}
+
+	anomalies = append(anomalies, c.detectStructuralAnomalies(results)...)
+
+	return anomalies
+}
+
+// pickMajority returns the key with the highest count in counts, breaking
+// ties deterministically by picking the lexically smallest key so repeated
+// runs over the same data (e.g. under --fail-on-anomaly in CI) always agree.
+func pickMajority(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+
+	return best
+}
+
+// detectStructuralAnomalies flags servers with a different version than
+// their peers, servers missing routes/gateways their cluster-mates have,
+// and servers whose clock has drifted from the local clock.
+func (c *SrvLsCmd) detectStructuralAnomalies(results []*srvLsResult) []srvLsAnomaly {
+	var anomalies []srvLsAnomaly
+
+	versions := map[string]int{}
+	byCluster := map[string][]*srvLsResult{}
+	for _, r := range results {
+		versions[r.Server.Version]++
+		byCluster[r.Server.Cluster] = append(byCluster[r.Server.Cluster], r)
+	}
+
+	majorityVersion := pickMajority(versions)
+
+	for _, r := range results {
+		if r.Server.Version != majorityVersion {
+			anomalies = append(anomalies, srvLsAnomaly{
+				server: r.Server.Name, cluster: r.Server.Cluster, metric: "version",
+				value:     r.Server.Version,
+				deviation: fmt.Sprintf("majority version is %s", majorityVersion),
+			})
+		}
+
+		skew := time.Since(r.Server.Time)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > 30*time.Second {
+			anomalies = append(anomalies, srvLsAnomaly{
+				server: r.Server.Name, cluster: r.Server.Cluster, metric: "clock_skew",
+				value:     skew.Round(time.Second).String(),
+				deviation: "exceeds 30s",
+			})
+		}
+	}
+
+	for cluster, peers := range byCluster {
+		if cluster == "" || len(peers) < 2 {
+			continue
+		}
+
+		maxRoutes := 0
+		maxGateways := 0
+		for _, r := range peers {
+			if n := len(r.Stats.Routes); n > maxRoutes {
+				maxRoutes = n
+			}
+			if n := len(r.Stats.Gateways); n > maxGateways {
+				maxGateways = n
+			}
+		}
+
+		for _, r := range peers {
+			if n := len(r.Stats.Routes); n < maxRoutes {
+				anomalies = append(anomalies, srvLsAnomaly{
+					server: r.Server.Name, cluster: cluster, metric: "routes",
+					value:     fmt.Sprintf("%d", n),
+					deviation: fmt.Sprintf("peers have up to %d", maxRoutes),
+				})
+			}
+
+			if n := len(r.Stats.Gateways); n < maxGateways {
+				anomalies = append(anomalies, srvLsAnomaly{
+					server: r.Server.Name, cluster: cluster, metric: "gateways",
+					value:     fmt.Sprintf("%d", n),
+					deviation: fmt.Sprintf("peers have up to %d", maxGateways),
+				})
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// renderAnomalies runs the anomaly pass and, if anything was flagged, prints
+// an Anomalies table to w. It returns true if at least one anomaly was
+// found.
+func (c *SrvLsCmd) renderAnomalies(results []*srvLsResult, w io.Writer) bool {
+	anomalies := c.detectAnomalies(results)
+	if len(anomalies) == 0 {
+		return false
+	}
+
+	fmt.Fprintln(w)
+	table := newTableWriter("Anomalies")
+	table.AddHeaders("Server", "Cluster", "Metric", "Value", "Deviation")
+	for _, a := range anomalies {
+		table.AddRow(a.server, a.cluster, a.metric, a.value, a.deviation)
+	}
+	fmt.Fprint(w, table.Render())
+
+	return true
+}
+
+// checkAnomalies runs the --anomalies pass, if requested, regardless of
+// which --output encoder was used, so `--anomalies --fail-on-anomaly` works
+// for CI/monitoring jobs using structured output and not just the table.
+func (c *SrvLsCmd) checkAnomalies(results []*srvLsResult, w io.Writer) error {
+	if !c.anomalies {
+		return nil
+	}
+
+	found := c.renderAnomalies(results, w)
+	if found && c.failOnAnomaly {
+		os.Exit(1)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,118 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline(nil); got != "" {
+		t.Fatalf("expected empty sparkline for nil series, got %q", got)
+	}
+
+	flat := sparkline([]float64{1, 1, 1})
+	if len([]rune(flat)) != 3 {
+		t.Fatalf("expected 3 glyphs, got %d", len([]rune(flat)))
+	}
+
+	rising := []rune(sparkline([]float64{0, 5, 10}))
+	if rising[0] != sparkBlocks[0] || rising[2] != sparkBlocks[len(sparkBlocks)-1] {
+		t.Fatalf("expected rising series to span low to high glyphs, got %v", string(rising))
+	}
+}
+
+func TestAppendSpark(t *testing.T) {
+	var series []float64
+	for i := 0; i < srvLsSparkWidth+5; i++ {
+		series = appendSpark(series, float64(i))
+	}
+
+	if len(series) != srvLsSparkWidth {
+		t.Fatalf("expected series capped at %d, got %d", srvLsSparkWidth, len(series))
+	}
+	if series[len(series)-1] != float64(srvLsSparkWidth+4) {
+		t.Fatalf("expected the most recent value to be retained, got %v", series[len(series)-1])
+	}
+}
+
+func TestSrvLsHistoryUpdate(t *testing.T) {
+	h := newSrvLsHistory()
+
+	ssm1 := newTestServerStatsMsg("s1", "east")
+	ssm1.Stats.InMsgs, ssm1.Stats.OutMsgs = 100, 50
+	ssm1.Stats.Connections = 10
+
+	first := h.update(ssm1, 5*time.Millisecond, time.Unix(0, 0))
+	if first.inMsgsPerSec != 0 || first.connDelta != 0 {
+		t.Fatalf("expected zero delta on first sample, got %+v", first)
+	}
+
+	ssm2 := newTestServerStatsMsg("s1", "east")
+	ssm2.Stats.InMsgs, ssm2.Stats.OutMsgs = 200, 150
+	ssm2.Stats.Connections = 12
+
+	second := h.update(ssm2, 15*time.Millisecond, time.Unix(1, 0))
+	if second.inMsgsPerSec != 100 {
+		t.Fatalf("expected 100 in msgs/sec, got %v", second.inMsgsPerSec)
+	}
+	if second.outMsgsPerSec != 100 {
+		t.Fatalf("expected 100 out msgs/sec, got %v", second.outMsgsPerSec)
+	}
+	if second.connDelta != 2 {
+		t.Fatalf("expected connDelta of 2, got %d", second.connDelta)
+	}
+	if second.rttJitter != 10*time.Millisecond {
+		t.Fatalf("expected rttJitter of 10ms, got %v", second.rttJitter)
+	}
+
+	if got := h.delta("s1"); got != second {
+		t.Fatalf("delta() = %+v, want cached %+v", got, second)
+	}
+}
+
+func TestMeanStddev(t *testing.T) {
+	mean, stddev := meanStddev([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if mean != 5 {
+		t.Fatalf("expected mean 5, got %v", mean)
+	}
+	if stddev != 2 {
+		t.Fatalf("expected stddev 2, got %v", stddev)
+	}
+
+	if mean, stddev := meanStddev(nil); mean != 0 || stddev != 0 {
+		t.Fatalf("expected zero mean/stddev for empty input, got %v/%v", mean, stddev)
+	}
+}
+
+func TestMedianMAD(t *testing.T) {
+	median, mad := medianMAD([]float64{1, 2, 3, 4, 100})
+	if median != 3 {
+		t.Fatalf("expected median 3, got %v", median)
+	}
+	if mad != 1 {
+		t.Fatalf("expected mad 1, got %v", mad)
+	}
+}
+
+func TestPickMajorityDeterministicTie(t *testing.T) {
+	counts := map[string]int{"2.10.1": 2, "2.10.0": 2, "2.9.5": 1}
+
+	for i := 0; i < 10; i++ {
+		if got := pickMajority(counts); got != "2.10.0" {
+			t.Fatalf("expected deterministic tie-break to pick 2.10.0, got %s", got)
+		}
+	}
+}
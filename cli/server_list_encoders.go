@@ -0,0 +1,217 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SrvLsEncoder renders polled `nats srv ls` results for one of the
+// pluggable --output formats. Server is called once per result, either as
+// replies stream in (when streams returns true, e.g. ndjson) or once the
+// full set has been polled and sorted (csv, template, json).
+type SrvLsEncoder interface {
+	// streams reports whether Server should be called as soon as each
+	// reply arrives rather than after the full poll has completed and been
+	// sorted.
+	streams() bool
+	// Prepare hands the encoder the cluster aggregates computed over the
+	// whole poll, before Server is called for any individual result. It is
+	// not called for encoders that stream, since they encode each result
+	// before the poll has finished and the aggregates are known.
+	Prepare(clusters map[string]*srvListCluster, totals *srvLsTotals)
+	// Server encodes a single result.
+	Server(r *srvLsResult) error
+	// Flush finalises the encoding once every result has been passed to
+	// Server, e.g. flushing a CSV writer or closing a JSON array.
+	Flush() error
+}
+
+// newSrvLsEncoder builds the SrvLsEncoder for the given --output value.
+// output may be "json", "csv", "ndjson" or "template=<go-template>".
+func newSrvLsEncoder(output string) (SrvLsEncoder, error) {
+	switch {
+	case output == "json":
+		return &srvLsJSONEncoder{}, nil
+	case output == "ndjson":
+		return &srvLsNDJSONEncoder{}, nil
+	case output == "csv":
+		return newSrvLsCSVEncoder(), nil
+	case strings.HasPrefix(output, "template="):
+		return newSrvLsTemplateEncoder(strings.TrimPrefix(output, "template="))
+	default:
+		return nil, fmt.Errorf("unknown output format %q", output)
+	}
+}
+
+// srvLsJSONEncoder buffers every result and emits them as a single JSON
+// array on Flush, matching the historic --json behaviour.
+type srvLsJSONEncoder struct {
+	results []*srvLsResult
+}
+
+func (e *srvLsJSONEncoder) streams() bool { return false }
+
+func (e *srvLsJSONEncoder) Prepare(_ map[string]*srvListCluster, _ *srvLsTotals) {}
+
+func (e *srvLsJSONEncoder) Server(r *srvLsResult) error {
+	e.results = append(e.results, r)
+	return nil
+}
+
+func (e *srvLsJSONEncoder) Flush() error {
+	printJSON(e.results)
+	return nil
+}
+
+// srvLsNDJSONEncoder prints one JSON object per server as ping replies
+// arrive, before sorting completes, so it can be piped into jq/fx as a
+// live stream rather than waiting for the whole poll to finish.
+type srvLsNDJSONEncoder struct{}
+
+func (e *srvLsNDJSONEncoder) streams() bool { return true }
+
+func (e *srvLsNDJSONEncoder) Prepare(_ map[string]*srvListCluster, _ *srvLsTotals) {}
+
+func (e *srvLsNDJSONEncoder) Server(r *srvLsResult) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Println(string(data))
+	return err
+}
+
+func (e *srvLsNDJSONEncoder) Flush() error {
+	return nil
+}
+
+// srvLsCSVColumns are the stable column headers emitted in CSV mode,
+// matching the Server Overview table.
+var srvLsCSVColumns = []string{"Name", "Cluster", "Host", "Version", "JS", "Conns", "Subs", "Routes", "GWs", "Mem", "CPU", "Cores", "Slow", "Uptime", "RTT"}
+
+// srvLsCSVEncoder writes the sorted results as CSV with a stable header
+// row matching the table output's columns.
+type srvLsCSVEncoder struct {
+	w *csv.Writer
+}
+
+func newSrvLsCSVEncoder() *srvLsCSVEncoder {
+	return newSrvLsCSVEncoderTo(os.Stdout)
+}
+
+// newSrvLsCSVEncoderTo builds a srvLsCSVEncoder writing to an arbitrary
+// io.Writer, split out from newSrvLsCSVEncoder so tests can assert on the
+// emitted CSV without going through os.Stdout.
+func newSrvLsCSVEncoderTo(w io.Writer) *srvLsCSVEncoder {
+	cw := csv.NewWriter(w)
+	cw.Write(srvLsCSVColumns)
+	return &srvLsCSVEncoder{w: cw}
+}
+
+func (e *srvLsCSVEncoder) streams() bool { return false }
+
+func (e *srvLsCSVEncoder) Prepare(_ map[string]*srvListCluster, _ *srvLsTotals) {}
+
+func (e *srvLsCSVEncoder) Server(r *srvLsResult) error {
+	jsEnabled := "no"
+	if r.Server.JetStream {
+		if r.Server.Domain != "" {
+			jsEnabled = r.Server.Domain
+		} else {
+			jsEnabled = "yes"
+		}
+	}
+
+	return e.w.Write([]string{
+		r.Server.Name,
+		r.Server.Cluster,
+		r.Server.Host,
+		r.Server.Version,
+		jsEnabled,
+		strconv.Itoa(r.Stats.Connections),
+		strconv.FormatUint(uint64(r.Stats.NumSubs), 10),
+		strconv.Itoa(len(r.Stats.Routes)),
+		strconv.Itoa(len(r.Stats.Gateways)),
+		strconv.FormatInt(r.Stats.Mem, 10),
+		strconv.FormatFloat(r.Stats.CPU, 'f', 2, 64),
+		strconv.Itoa(r.Stats.Cores),
+		strconv.FormatInt(r.Stats.SlowConsumers, 10),
+		r.Server.Time.Sub(r.Stats.Start).String(),
+		r.RTT.String(),
+	})
+}
+
+func (e *srvLsCSVEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// srvLsTemplateData is what a --output template=<tmpl> has access to: the
+// full ping reply, the derived RTT, and the cluster aggregates computed
+// over the whole poll. The fields of Clusters and Totals are unexported, so
+// a template can print them whole (e.g. {{.Totals}}) but cannot reach an
+// individual aggregate such as a server or connection count.
+type srvLsTemplateData struct {
+	*srvLsResult
+	RTT      time.Duration
+	Clusters map[string]*srvListCluster
+	Totals   *srvLsTotals
+}
+
+// srvLsTemplateEncoder executes a user supplied Go template once per
+// server, exposing the full ServerStatsMsg plus derived fields.
+type srvLsTemplateEncoder struct {
+	tmpl     *template.Template
+	clusters map[string]*srvListCluster
+	totals   *srvLsTotals
+}
+
+func newSrvLsTemplateEncoder(text string) (*srvLsTemplateEncoder, error) {
+	tmpl, err := template.New("srv-ls").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --output template: %w", err)
+	}
+
+	return &srvLsTemplateEncoder{tmpl: tmpl}, nil
+}
+
+func (e *srvLsTemplateEncoder) Prepare(clusters map[string]*srvListCluster, totals *srvLsTotals) {
+	e.clusters = clusters
+	e.totals = totals
+}
+
+func (e *srvLsTemplateEncoder) streams() bool { return false }
+
+func (e *srvLsTemplateEncoder) Server(r *srvLsResult) error {
+	return e.tmpl.Execute(os.Stdout, &srvLsTemplateData{
+		srvLsResult: r,
+		RTT:         r.RTT,
+		Clusters:    e.clusters,
+		Totals:      e.totals,
+	})
+}
+
+func (e *srvLsTemplateEncoder) Flush() error {
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSrvLsResult(name, cluster, domain string, rtt time.Duration) *srvLsResult {
+	ssm := newTestServerStatsMsg(name, cluster)
+	ssm.Server.JetStream = domain != ""
+	ssm.Server.Domain = domain
+	return &srvLsResult{ServerStatsMsg: ssm, RTT: rtt}
+}
+
+func TestSrvLsCSVEncoderJSColumn(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newSrvLsCSVEncoderTo(&buf)
+
+	if err := enc.Server(newTestSrvLsResult("s1", "east", "hub", 5*time.Millisecond)); err != nil {
+		t.Fatalf("Server() returned error: %s", err)
+	}
+	if err := enc.Server(newTestSrvLsResult("s2", "east", "", 5*time.Millisecond)); err != nil {
+		t.Fatalf("Server() returned error: %s", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %s", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("could not parse emitted CSV: %s", err)
+	}
+
+	jsCol := -1
+	for i, h := range rows[0] {
+		if h == "JS" {
+			jsCol = i
+		}
+	}
+	if jsCol == -1 {
+		t.Fatalf("expected a JS column in header, got %v", rows[0])
+	}
+
+	if rows[1][jsCol] != "hub" {
+		t.Fatalf("expected JetStream domain %q in JS column, got %q", "hub", rows[1][jsCol])
+	}
+	if rows[2][jsCol] != "no" {
+		t.Fatalf("expected %q in JS column for a non-JetStream server, got %q", "no", rows[2][jsCol])
+	}
+}
+
+func TestSrvLsResultMarshalsRTT(t *testing.T) {
+	r := newTestSrvLsResult("s1", "east", "", 12*time.Millisecond)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %s", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("could not unmarshal encoded result: %s", err)
+	}
+
+	if _, ok := decoded["rtt"]; !ok {
+		t.Fatalf("expected encoded result to include an rtt field, got %s", data)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// newTestServerStatsMsg builds a minimal, valid server.ServerStatsMsg for
+// use across this package's table-driven tests.
+func newTestServerStatsMsg(name, cluster string) *server.ServerStatsMsg {
+	return &server.ServerStatsMsg{
+		Server: server.ServerInfo{
+			Name:    name,
+			Cluster: cluster,
+			Host:    "127.0.0.1",
+			Version: "2.10.0",
+			Time:    time.Now(),
+		},
+		Stats: server.ServerStats{
+			Connections: 10,
+			NumSubs:     5,
+			Mem:         1024,
+			CPU:         12.5,
+			Cores:       4,
+			Start:       time.Now().Add(-time.Hour),
+		},
+	}
+}
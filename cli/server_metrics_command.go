@@ -0,0 +1,434 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/choria-io/fisk"
+	"github.com/golang/snappy"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// SrvMetricsCmd polls $SYS.REQ.SERVER.PING on an interval and forwards the
+// decoded server.Statsz as Prometheus metrics, either by pushing them to a
+// remote_write endpoint / Pushgateway or by serving them on a /metrics
+// listener for Prometheus to scrape directly.
+type SrvMetricsCmd struct {
+	expect      uint32
+	interval    time.Duration
+	remoteWrite string
+	pushgateway string
+	listen      string
+	maxQueue    int
+}
+
+func configureServerMetricsCommand(srv *fisk.CmdClause) {
+	c := &SrvMetricsCmd{}
+
+	metrics := srv.Command("metrics", "Expose $SYS.REQ.SERVER.PING stats as Prometheus metrics").Action(c.metrics)
+	metrics.Flag("interval", "Polling interval").Default("10s").DurationVar(&c.interval)
+	metrics.Flag("expect", "How many servers to expect on each poll").Uint32Var(&c.expect)
+	metrics.Flag("remote-write", "Prometheus remote_write URL to push samples to").StringVar(&c.remoteWrite)
+	metrics.Flag("pushgateway", "Prometheus Pushgateway URL to push a text-exposition snapshot to").StringVar(&c.pushgateway)
+	metrics.Flag("listen", "Address to serve a /metrics text-exposition endpoint on, for Prometheus to scrape").StringVar(&c.listen)
+	metrics.Flag("max-queue", "Maximum number of batches to hold in memory before dropping the oldest").Default("100").IntVar(&c.maxQueue)
+}
+
+// srvMetricSample is a single Prometheus sample derived from one field of a
+// server.ServerStatsMsg.
+type srvMetricSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+	ts     time.Time
+}
+
+// statszSamples flattens every numeric field of ssm.Stats we care about into
+// a flat list of labelled samples ready for any of the exposition formats.
+func statszSamples(ssm *server.ServerStatsMsg, now time.Time) []srvMetricSample {
+	labels := map[string]string{
+		"server":  ssm.Server.Name,
+		"cluster": ssm.Server.Cluster,
+		"domain":  ssm.Server.Domain,
+		"version": ssm.Server.Version,
+		"host":    ssm.Server.Host,
+	}
+
+	add := func(samples []srvMetricSample, name string, value float64) []srvMetricSample {
+		return append(samples, srvMetricSample{name: name, labels: labels, value: value, ts: now})
+	}
+
+	var samples []srvMetricSample
+	samples = add(samples, "nats_server_connections", float64(ssm.Stats.Connections))
+	samples = add(samples, "nats_server_total_connections", float64(ssm.Stats.TotalConnections))
+	samples = add(samples, "nats_server_num_subscriptions", float64(ssm.Stats.NumSubs))
+	samples = add(samples, "nats_server_num_routes", float64(len(ssm.Stats.Routes)))
+	samples = add(samples, "nats_server_num_gateways", float64(len(ssm.Stats.Gateways)))
+	samples = add(samples, "nats_server_mem_bytes", float64(ssm.Stats.Mem))
+	samples = add(samples, "nats_server_cpu_percent", ssm.Stats.CPU)
+	samples = add(samples, "nats_server_slow_consumers", float64(ssm.Stats.SlowConsumers))
+	samples = add(samples, "nats_server_in_msgs_total", float64(ssm.Stats.InMsgs))
+	samples = add(samples, "nats_server_out_msgs_total", float64(ssm.Stats.OutMsgs))
+	samples = add(samples, "nats_server_in_bytes_total", float64(ssm.Stats.InBytes))
+	samples = add(samples, "nats_server_out_bytes_total", float64(ssm.Stats.OutBytes))
+
+	if js := ssm.Stats.JetStream; js != nil && js.Stats != nil {
+		samples = add(samples, "nats_server_jetstream_memory_bytes", float64(js.Stats.Memory))
+		samples = add(samples, "nats_server_jetstream_store_bytes", float64(js.Stats.Store))
+		samples = add(samples, "nats_server_jetstream_accounts", float64(js.Stats.Accounts))
+		samples = add(samples, "nats_server_jetstream_ha_assets", float64(js.Stats.HAAssets))
+	}
+
+	return samples
+}
+
+// srvMetricsQueue is a bounded queue of shards, each one poll's worth of
+// samples awaiting delivery. The queue keeps an exponentially weighted moving average of how many samples
+// are produced per poll so it can auto-tune how large the next remote_write
+// batch should be.
+type srvMetricsQueue struct {
+	mu      sync.Mutex
+	shards  [][]srvMetricSample
+	maxSize int
+	ewma    float64
+}
+
+func newSrvMetricsQueue(maxSize int) *srvMetricsQueue {
+	return &srvMetricsQueue{maxSize: maxSize}
+}
+
+// push enqueues a poll's worth of samples as one shard, dropping the oldest
+// shard if the queue has grown past maxSize.
+func (q *srvMetricsQueue) push(samples []srvMetricSample) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	const ewmaAlpha = 0.3
+	if q.ewma == 0 {
+		q.ewma = float64(len(samples))
+	} else {
+		q.ewma = ewmaAlpha*float64(len(samples)) + (1-ewmaAlpha)*q.ewma
+	}
+
+	q.shards = append(q.shards, samples)
+	for len(q.shards) > q.maxSize {
+		log.Printf("metrics queue full, dropping oldest batch of %d samples", len(q.shards[0]))
+		q.shards = q.shards[1:]
+	}
+}
+
+// batchSize returns the queue's current auto-tuned batch size: the EWMA
+// truncated to an integer, and never less than 1.
+func (q *srvMetricsQueue) batchSize() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.ewma < 1 {
+		return 1
+	}
+	return int(q.ewma)
+}
+
+// drain removes whole shards from the front of the queue until at least n
+// samples have been collected or the queue is empty, and returns their
+// samples. Because shards are never split, the result can exceed n.
+func (q *srvMetricsQueue) drain(n int) []srvMetricSample {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var out []srvMetricSample
+	for len(q.shards) > 0 && len(out) < n {
+		out = append(out, q.shards[0]...)
+		q.shards = q.shards[1:]
+	}
+
+	return out
+}
+
+func (c *SrvMetricsCmd) metrics(_ *fisk.ParseContext) error {
+	if c.remoteWrite == "" && c.pushgateway == "" && c.listen == "" {
+		return fmt.Errorf("one of --remote-write, --pushgateway or --listen is required")
+	}
+
+	nc, err := newNatsConn("", natsOpts()...)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	queue := newSrvMetricsQueue(c.maxQueue)
+	var latest sync.Map // server name -> []srvMetricSample, used by the /metrics listener
+
+	if c.listen != "" {
+		go c.serve(&latest)
+	}
+
+	for {
+		now := time.Now()
+		var mu sync.Mutex
+		var batch []srvMetricSample
+
+		doReqAsync(nil, "$SYS.REQ.SERVER.PING", int(c.expect), nc, func(data []byte) {
+			ssm := &server.ServerStatsMsg{}
+			if err := json.Unmarshal(data, ssm); err != nil {
+				log.Printf("Could not decode response: %s", err)
+				return
+			}
+
+			samples := statszSamples(ssm, now)
+			latest.Store(ssm.Server.Name, samples)
+
+			mu.Lock()
+			batch = append(batch, samples...)
+			mu.Unlock()
+		})
+
+		if len(batch) > 0 {
+			queue.push(batch)
+
+			if c.remoteWrite != "" {
+				c.drainRemoteWrite(queue)
+			}
+
+			if c.pushgateway != "" {
+				if err := pushToGateway(c.pushgateway, batch); err != nil {
+					log.Printf("Pushgateway push failed: %s", err)
+				}
+			}
+		}
+
+		time.Sleep(c.interval)
+	}
+}
+
+// remoteWriteRetryableError marks a remote_write failure as transient, i.e.
+// worth retrying with backoff rather than discarding the batch outright.
+type remoteWriteRetryableError struct{ status string }
+
+func (e *remoteWriteRetryableError) Error() string {
+	return fmt.Sprintf("remote_write endpoint returned %s", e.status)
+}
+
+// drainRemoteWrite sends auto-tuned batches to the configured remote_write
+// endpoint, retrying with exponential backoff on 5xx responses and on
+// transport errors (no response at all). A 4xx
+// means the batch itself is bad and retrying it would just delay every
+// batch queued behind it, so those are logged and dropped immediately.
+func (c *SrvMetricsCmd) drainRemoteWrite(queue *srvMetricsQueue) {
+	for {
+		batch := queue.drain(queue.batchSize())
+		if len(batch) == 0 {
+			return
+		}
+
+		backoff := 500 * time.Millisecond
+		for attempt := 0; attempt < 5; attempt++ {
+			err := sendRemoteWrite(c.remoteWrite, batch)
+			if err == nil {
+				break
+			}
+
+			var retryable *remoteWriteRetryableError
+			if !errors.As(err, &retryable) {
+				log.Printf("remote_write batch rejected, dropping it: %s", err)
+				break
+			}
+
+			log.Printf("remote_write attempt %d failed: %s", attempt+1, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// sendRemoteWrite encodes samples as a snappy-compressed protobuf
+// WriteRequest and POSTs it per the Prometheus remote_write protocol. The
+// WriteRequest is hand-encoded against the remote_write wire format
+// instead of depending on the full prometheus/prometheus module just for
+// this one small message.
+func sendRemoteWrite(url string, samples []srvMetricSample) error {
+	compressed := snappy.Encode(nil, encodeWriteRequest(samples))
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(compressed))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return &remoteWriteRetryableError{status: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 5 {
+		return &remoteWriteRetryableError{status: resp.Status}
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote_write endpoint rejected batch: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// The remote_write WriteRequest protobuf schema is small enough to encode
+// by hand rather than pulling in prometheus/prometheus/prompb and its
+// protobuf runtime dependency:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label        { string name = 1; string value = 2; }
+//	message Sample       { double value = 1; int64 timestamp = 2; }
+
+func encodeWriteRequest(samples []srvMetricSample) []byte {
+	var buf []byte
+	for _, s := range samples {
+		buf = appendTaggedMessage(buf, 1, encodeTimeSeries(s))
+	}
+	return buf
+}
+
+func encodeTimeSeries(s srvMetricSample) []byte {
+	var buf []byte
+	buf = appendTaggedMessage(buf, 1, encodeLabel("__name__", s.name))
+	for k, v := range s.labels {
+		if v == "" {
+			continue
+		}
+		buf = appendTaggedMessage(buf, 1, encodeLabel(k, v))
+	}
+	buf = appendTaggedMessage(buf, 2, encodeSample(s.value, s.ts))
+	return buf
+}
+
+func encodeLabel(name, value string) []byte {
+	var buf []byte
+	buf = appendTaggedString(buf, 1, name)
+	buf = appendTaggedString(buf, 2, value)
+	return buf
+}
+
+func encodeSample(value float64, ts time.Time) []byte {
+	var buf []byte
+	buf = append(buf, wireTag(1, 1)) // field 1, 64-bit wire type
+	var bits [8]byte
+	binary.LittleEndian.PutUint64(bits[:], math.Float64bits(value))
+	buf = append(buf, bits[:]...)
+	buf = append(buf, wireTag(2, 0)) // field 2, varint wire type
+	buf = appendVarint(buf, uint64(ts.UnixMilli()))
+	return buf
+}
+
+func wireTag(field, wireType int) byte {
+	return byte(field<<3 | wireType)
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendTaggedString(buf []byte, field int, s string) []byte {
+	buf = append(buf, wireTag(field, 2))
+	buf = appendVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendTaggedMessage(buf []byte, field int, msg []byte) []byte {
+	buf = append(buf, wireTag(field, 2))
+	buf = appendVarint(buf, uint64(len(msg)))
+	return append(buf, msg...)
+}
+
+// pushToGateway POSTs a plain text-exposition snapshot to a Prometheus
+// Pushgateway job.
+func pushToGateway(url string, samples []srvMetricSample) error {
+	resp, err := http.Post(strings.TrimSuffix(url, "/")+"/metrics/job/natscli", "text/plain", strings.NewReader(renderExposition(samples)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway rejected batch: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// serve runs a plain HTTP listener exposing the latest known sample for
+// every server in Prometheus text-exposition format, so Prometheus can
+// scrape natscli directly without a push path.
+func (c *SrvMetricsCmd) serve(latest *sync.Map) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		var all []srvMetricSample
+		latest.Range(func(_, v any) bool {
+			all = append(all, v.([]srvMetricSample)...)
+			return true
+		})
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, renderExposition(all))
+	})
+
+	log.Printf("Serving Prometheus metrics on http://%s/metrics", c.listen)
+	if err := http.ListenAndServe(c.listen, mux); err != nil {
+		log.Printf("metrics listener stopped: %s", err)
+	}
+}
+
+// renderExposition renders samples in the Prometheus text exposition
+// format, one line per sample sorted by metric name and then server name
+// for stable output.
+func renderExposition(samples []srvMetricSample) string {
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].name != samples[j].name {
+			return samples[i].name < samples[j].name
+		}
+		return samples[i].labels["server"] < samples[j].labels["server"]
+	})
+
+	var buf strings.Builder
+	for _, s := range samples {
+		var labelPairs []string
+		for k, v := range s.labels {
+			if v == "" {
+				continue
+			}
+			labelPairs = append(labelPairs, fmt.Sprintf("%s=%q", k, v))
+		}
+		sort.Strings(labelPairs)
+
+		fmt.Fprintf(&buf, "%s{%s} %v\n", s.name, strings.Join(labelPairs, ","), s.value)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAppendVarint(t *testing.T) {
+	cases := map[uint64][]byte{
+		0:   {0x00},
+		1:   {0x01},
+		127: {0x7f},
+		128: {0x80, 0x01},
+		300: {0xac, 0x02},
+	}
+
+	for in, want := range cases {
+		got := appendVarint(nil, in)
+		if len(got) != len(want) {
+			t.Fatalf("appendVarint(%d) = %v, want %v", in, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("appendVarint(%d) = %v, want %v", in, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodeSampleRoundTrip(t *testing.T) {
+	data := encodeSample(42.5, time.UnixMilli(1000))
+
+	// field 1: fixed64 value
+	if data[0] != wireTag(1, 1) {
+		t.Fatalf("expected field 1 fixed64 tag, got %x", data[0])
+	}
+	bits := binary.LittleEndian.Uint64(data[1:9])
+	if math.Float64frombits(bits) != 42.5 {
+		t.Fatalf("expected value 42.5, got %v", math.Float64frombits(bits))
+	}
+
+	if data[9] != wireTag(2, 0) {
+		t.Fatalf("expected field 2 varint tag, got %x", data[9])
+	}
+}
+
+func TestSrvMetricsQueuePushDrain(t *testing.T) {
+	q := newSrvMetricsQueue(2)
+
+	q.push([]srvMetricSample{{name: "a"}, {name: "b"}})
+	if bs := q.batchSize(); bs < 1 {
+		t.Fatalf("expected a positive batch size, got %d", bs)
+	}
+
+	q.push([]srvMetricSample{{name: "c"}})
+	q.push([]srvMetricSample{{name: "d"}}) // exceeds maxSize, oldest shard dropped
+
+	drained := q.drain(10)
+	var names []string
+	for _, s := range drained {
+		names = append(names, s.name)
+	}
+
+	if len(names) == 0 || names[0] == "a" {
+		t.Fatalf("expected oldest shard to have been dropped, got %v", names)
+	}
+}
+
+func TestStatszSamplesIncludesCoreFields(t *testing.T) {
+	ssm := newTestServerStatsMsg("s1", "east")
+
+	samples := statszSamples(ssm, ssm.Server.Time)
+
+	found := map[string]bool{}
+	for _, s := range samples {
+		found[s.name] = true
+	}
+
+	for _, name := range []string{"nats_server_connections", "nats_server_mem_bytes", "nats_server_cpu_percent"} {
+		if !found[name] {
+			t.Fatalf("expected sample %s to be present", name)
+		}
+	}
+}